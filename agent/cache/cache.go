@@ -0,0 +1,149 @@
+package cache
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// RequestInfo holds the cache-affecting metadata a Request must expose: the
+// key used to deduplicate requests for the same data, and for cache types
+// that don't support blocking, how long a cached result may be reused
+// before Notify/Get must re-fetch it.
+type RequestInfo struct {
+	// Key uniquely identifies this request within its cache type.
+	Key string
+
+	// MaxAge is how long a polling cache type's result may be served from
+	// cache before it needs to be refreshed. Ignored by types that
+	// support blocking.
+	MaxAge time.Duration
+}
+
+// Request is implemented by the argument passed to Cache.Get/Notify for
+// every registered cache type. CacheInfo supplies the metadata the Cache
+// needs to key and, for polling types, age the cached result.
+type Request interface {
+	CacheInfo() RequestInfo
+}
+
+// ResultMeta is returned alongside a Get/Notify result with metadata about
+// how it was produced.
+type ResultMeta struct {
+	// Index is the result's index as returned by the type's Fetch. For
+	// blocking-capable types this is the value to pass as the index on
+	// the next blocking fetch.
+	Index uint64
+
+	// Hit indicates the result was served from cache without a fetch.
+	Hit bool
+
+	// Age is how long ago the result was fetched.
+	Age time.Duration
+}
+
+// FetchOptions are passed to a Type's Fetch method describing what the
+// caller already has cached, if anything.
+type FetchOptions struct {
+	// MinIndex is the index of the last result the caller has. A value of
+	// 0 means the caller has nothing cached.
+	MinIndex uint64
+}
+
+// FetchResult is returned by a Type's Fetch method.
+type FetchResult struct {
+	Value interface{}
+	Index uint64
+}
+
+// Type is implemented by every cache type registered with a Cache (for
+// example catalog services, health checks, and intentions).
+type Type interface {
+	// Fetch returns the latest value for the request, blocking until a
+	// value newer than opts.MinIndex is available if SupportsBlocking
+	// returns true.
+	Fetch(opts FetchOptions, r Request) (FetchResult, error)
+
+	// SupportsBlocking reports whether Fetch honors opts.MinIndex and
+	// blocks for a change, as opposed to always returning immediately and
+	// relying on the caller to poll at RequestInfo.MaxAge intervals.
+	SupportsBlocking() bool
+}
+
+// RegisterOptions configures how a Cache drives a registered Type's Fetch
+// calls, both for direct Get and for the notify loops behind
+// Notify/NotifySubscribe.
+type RegisterOptions struct {
+	// Backoff controls how long the notify loops sleep between retries
+	// after a failed fetch. Defaults to the package's built-in exponential
+	// backoff (backOffWait) if unset.
+	Backoff BackoffStrategy
+
+	// Equal is consulted by notifyPollingQuery to decide whether a newly
+	// fetched result differs from the last one it emitted. Defaults to
+	// reflect.DeepEqual (defaultEqual) if unset. Ignored by types that
+	// support blocking, since those rely on the index returned by Fetch
+	// instead.
+	Equal EqualFunc
+}
+
+type typeEntry struct {
+	Type Type
+	Opts RegisterOptions
+}
+
+// Cache is an agent-local cache of results fetched from Consul servers.
+// NotifySubscribe shares a single underlying query across every subscriber
+// watching the same (type, Request) rather than having each drive its own.
+type Cache struct {
+	typesLock sync.RWMutex
+	types     map[string]typeEntry
+
+	// notifyLock guards notifyPublishers and notifyBackoffs, including the
+	// atomic create-or-join decision in getOrCreatePublisher; see that
+	// method's doc comment.
+	notifyLock sync.Mutex
+
+	// notifyPublishers holds the shared notifyPublisher driving the query
+	// for every (type, Request) with at least one active NotifySubscribe
+	// caller, keyed by "<type>:<CacheInfo().Key>".
+	notifyPublishers map[string]*notifyPublisher
+
+	// notifyBackoffs holds the per-publisher clone of a stateful
+	// BackoffStrategy (see statefulBackoffStrategy), keyed the same way as
+	// notifyPublishers, for as long as that publisher is live. Stateless
+	// strategies never appear here; they're shared as-is.
+	notifyBackoffs map[string]BackoffStrategy
+}
+
+// getWithIndex fetches the result for (t, r), blocking until a value newer
+// than minIndex is available if the registered type supports blocking.
+func (c *Cache) getWithIndex(t string, r Request, minIndex uint64) (interface{}, ResultMeta, error) {
+	c.typesLock.RLock()
+	tEntry, ok := c.types[t]
+	c.typesLock.RUnlock()
+	if !ok {
+		return nil, ResultMeta{}, fmt.Errorf("unknown type in cache: %s", t)
+	}
+
+	res, err := tEntry.Type.Fetch(FetchOptions{MinIndex: minIndex}, r)
+	if err != nil {
+		return res.Value, ResultMeta{Index: res.Index}, err
+	}
+	return res.Value, ResultMeta{Index: res.Index}, nil
+}
+
+// backOffWait reproduces the package's original hard-coded notify-loop
+// backoff: 2^failures seconds, capped at 1 minute. It's used whenever a
+// RegisterOptions doesn't set a BackoffStrategy.
+func backOffWait(failures uint) time.Duration {
+	shift := failures
+	if shift > 6 {
+		shift = 6
+	}
+	wait := time.Duration(1<<shift) * time.Second
+	if wait > time.Minute {
+		wait = time.Minute
+	}
+	return wait
+}
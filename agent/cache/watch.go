@@ -4,9 +4,11 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"sync"
 	"time"
 
 	"github.com/hashicorp/consul/lib"
+	"github.com/mitchellh/hashstructure"
 )
 
 // UpdateEvent is a struct summarising an update to a cache entry
@@ -21,6 +23,627 @@ type UpdateEvent struct {
 	Result        interface{}
 	Meta          ResultMeta
 	Err           error
+
+	// Stale is true when Result and Meta are carried over from the last
+	// successful fetch because the fetch that produced this event failed.
+	// It is only ever set on events emitted because of EmitErrors; Err is
+	// non-nil whenever Stale is true.
+	Stale bool
+
+	// ConsecutiveFailures is the number of fetches that have failed in a
+	// row as of this event, including the one that produced it if Stale is
+	// true. It is 0 on events produced by a successful fetch.
+	ConsecutiveFailures int
+
+	// LastSuccess is the time of the last fetch that succeeded, or the zero
+	// Time if there hasn't been one yet.
+	LastSuccess time.Time
+}
+
+// BackoffStrategy computes how long a notify loop should sleep after
+// consecutive failed fetches before retrying. It is consulted once per
+// failure by the shared blocking-query publisher (runNotifyPublisher) and
+// by notifyPollingQuery.
+//
+// failures is the number of consecutive failed fetches, including the one
+// that just happened, so implementations are called with failures >= 1.
+// Implementations must be safe for concurrent use since the same strategy
+// may back several notify loops at once.
+type BackoffStrategy interface {
+	// Wait returns how long to sleep before the next retry given the
+	// current number of consecutive failures.
+	Wait(failures uint) time.Duration
+}
+
+// BackoffStrategyFunc adapts a plain func to a BackoffStrategy.
+type BackoffStrategyFunc func(failures uint) time.Duration
+
+// Wait implements BackoffStrategy.
+func (f BackoffStrategyFunc) Wait(failures uint) time.Duration {
+	return f(failures)
+}
+
+// statefulBackoffStrategy is implemented by BackoffStrategy implementations
+// that track state across calls (such as DecorrelatedJitterBackoff's prev)
+// and therefore must not be shared between unrelated notify loops.
+// getOrCreatePublisher clones one instance per publisher via
+// scopedBackoffLocked, and NotifySubscribe one per polling loop, so a single RegisterOptions.Backoff can be registered for
+// a type without its state leaking between that type's distinct Request
+// keys.
+type statefulBackoffStrategy interface {
+	BackoffStrategy
+
+	// clone returns a new BackoffStrategy with the same configuration but
+	// independent internal state.
+	clone() BackoffStrategy
+}
+
+// defaultBackoffStrategy reproduces the notify package's original
+// hard-coded backOffWait behavior and is used whenever a RegisterOptions
+// doesn't set Backoff.
+var defaultBackoffStrategy BackoffStrategy = BackoffStrategyFunc(backOffWait)
+
+// ExponentialBackoff is a BackoffStrategy that doubles the wait time on each
+// consecutive failure, picked uniformly at random between 0 and the
+// doubled value ("full jitter"), and capped at Max.
+type ExponentialBackoff struct {
+	// Base is the upper bound of the jittered wait for the first failure.
+	// Defaults to 1 second if zero.
+	Base time.Duration
+
+	// Max caps the computed wait duration. Defaults to 1 minute if zero.
+	Max time.Duration
+}
+
+// Wait implements BackoffStrategy.
+func (b ExponentialBackoff) Wait(failures uint) time.Duration {
+	base := b.Base
+	if base <= 0 {
+		base = time.Second
+	}
+	max := b.Max
+	if max <= 0 {
+		max = time.Minute
+	}
+
+	shift := failures - 1
+	if shift > 31 {
+		shift = 31
+	}
+	capped := base * (1 << shift)
+	if capped <= 0 || capped > max {
+		capped = max
+	}
+	return lib.RandomStagger(capped)
+}
+
+// DecorrelatedJitterBackoff is a BackoffStrategy that picks each wait
+// uniformly at random between Base and three times the previous wait,
+// capped at Max. It tends to spread out retries more evenly over time than
+// ExponentialBackoff's full jitter while still backing off quickly under
+// sustained failure. It tracks the previous wait as internal state, so a
+// single DecorrelatedJitterBackoff must not be shared between unrelated
+// notify loops directly; register it via RegisterOptions.Backoff and
+// getOrCreatePublisher will clone a separate instance per publisher (see
+// statefulBackoffStrategy) rather than sharing the registered one. A call
+// with failures <= 1 discards the previous wait and starts over from Base,
+// so a new failure streak after a success does not compound off the last
+// one.
+type DecorrelatedJitterBackoff struct {
+	// Base is the minimum wait duration and the value used for the first
+	// failure. Defaults to 1 second if zero.
+	Base time.Duration
+
+	// Max caps the computed wait duration. Defaults to 1 minute if zero.
+	Max time.Duration
+
+	mu   sync.Mutex
+	prev time.Duration
+}
+
+// clone implements statefulBackoffStrategy.
+func (b *DecorrelatedJitterBackoff) clone() BackoffStrategy {
+	return &DecorrelatedJitterBackoff{Base: b.Base, Max: b.Max}
+}
+
+// Wait implements BackoffStrategy.
+func (b *DecorrelatedJitterBackoff) Wait(failures uint) time.Duration {
+	base := b.Base
+	if base <= 0 {
+		base = time.Second
+	}
+	max := b.Max
+	if max <= 0 {
+		max = time.Minute
+	}
+
+	b.mu.Lock()
+	prev := b.prev
+	if failures <= 1 || prev < base {
+		prev = base
+	}
+	ceiling := prev * 3
+	if ceiling <= 0 || ceiling > max {
+		ceiling = max
+	}
+	wait := base + lib.RandomStagger(ceiling-base)
+	b.prev = wait
+	b.mu.Unlock()
+
+	return wait
+}
+
+// EqualFunc reports whether next is equivalent to prev for the purposes of
+// deciding whether notifyPollingQuery should emit an UpdateEvent. It is
+// consulted instead of reflect.DeepEqual whenever a RegisterOptions sets
+// Equal, letting polling types with large results (service/health lists,
+// intentions) supply a cheap content hash or version comparison instead of
+// paying for a deep comparison on every poll.
+type EqualFunc func(prev, next interface{}) bool
+
+// defaultEqual is used whenever a RegisterOptions doesn't set Equal. It
+// preserves notifyPollingQuery's original reflect.DeepEqual behavior.
+func defaultEqual(prev, next interface{}) bool {
+	return reflect.DeepEqual(prev, next)
+}
+
+// HashEqual returns an EqualFunc that compares prev and next by hashing both
+// with hashstructure instead of deep-comparing them field by field. It's a
+// reasonable default for types that don't have a cheaper version or index to
+// compare and want to opt out of reflect.DeepEqual without writing custom
+// comparison code. If either value fails to hash, it conservatively reports
+// them as unequal so a real change is never silently swallowed.
+func HashEqual() EqualFunc {
+	return func(prev, next interface{}) bool {
+		prevHash, prevErr := hashstructure.Hash(prev, nil)
+		nextHash, nextErr := hashstructure.Hash(next, nil)
+		if prevErr != nil || nextErr != nil {
+			return false
+		}
+		return prevHash == nextHash
+	}
+}
+
+// BackpressurePolicy controls how a Notify subscriber's channel is fed when
+// the subscriber can't keep up with the rate at which the shared underlying
+// query is producing UpdateEvents.
+type BackpressurePolicy int
+
+const (
+	// BlockPublisher blocks the shared query until the subscriber's channel
+	// has room. This is the original, and still the default, Notify
+	// behavior. A single slow subscriber will hold back delivery to every
+	// other subscriber sharing the same query.
+	BlockPublisher BackpressurePolicy = iota
+
+	// DropOldest never blocks the shared query. Updates wait in an internal
+	// buffer sized to the subscriber channel's capacity (at least 1); when
+	// that buffer is full, its oldest UpdateEvent is discarded to make room
+	// for the newest one. Updates already sent into the channel are never
+	// discarded, so a slow subscriber may still read older updates first.
+	DropOldest
+
+	// Coalesce never blocks the shared query. Only the most recently
+	// published UpdateEvent is retained while the subscriber is behind;
+	// once it catches up it receives the latest state rather than every
+	// intermediate update.
+	Coalesce
+)
+
+// SubscribeOptions configures how a single subscriber added via
+// NotifySubscribe receives updates from a query shared with other
+// subscribers of the same (type, Request).
+type SubscribeOptions struct {
+	// Policy selects the BackpressurePolicy applied to this subscriber.
+	// Defaults to BlockPublisher.
+	Policy BackpressurePolicy
+
+	// EmitErrors opts this subscriber in to receiving UpdateEvents for
+	// fetches that failed, with Stale set and Result/Meta carried over from
+	// the last successful fetch. By default failed fetches are silent to
+	// the subscriber (other than via the eventual backoff-induced delay),
+	// matching the original Notify behavior.
+	EmitErrors bool
+
+	// MinErrorInterval rate-limits how often this subscriber is sent error
+	// events: once one is sent, further error events within
+	// MinErrorInterval are dropped, whether or not their error differs. It
+	// has no effect unless EmitErrors is set. Zero means no rate limiting
+	// beyond SuppressUnchanged.
+	MinErrorInterval time.Duration
+
+	// SuppressUnchanged skips emitting a repeat error event to this
+	// subscriber when the error is the same (by its Error() string) as the
+	// last one delivered to it. It has no effect unless EmitErrors is set.
+	SuppressUnchanged bool
+}
+
+// notifySubscription is one subscriber of a notifyPublisher. It applies the
+// subscriber's own BackpressurePolicy independently of any other subscriber
+// sharing the same publisher.
+type notifySubscription struct {
+	correlationID string
+	out           chan<- UpdateEvent
+	opts          SubscribeOptions
+
+	// mu guards queue/pending (DropOldest/Coalesce policies) and the
+	// error-gating state below.
+	mu          sync.Mutex
+	pending     *UpdateEvent
+	queue       []UpdateEvent
+	queueCap    int
+	wake        chan struct{}
+	lastErrEmit time.Time
+	lastErr     error
+}
+
+func newNotifySubscription(correlationID string, ch chan<- UpdateEvent, opts SubscribeOptions) *notifySubscription {
+	// out is send-only, so DropOldest can't make room by receiving from
+	// it directly; buffer internally instead and size the buffer to
+	// match the channel the caller gave us.
+	queueCap := cap(ch)
+	if queueCap < 1 {
+		queueCap = 1
+	}
+	return &notifySubscription{
+		correlationID: correlationID,
+		out:           ch,
+		opts:          opts,
+		queueCap:      queueCap,
+		wake:          make(chan struct{}, 1),
+	}
+}
+
+// deliver hands u off to the subscriber according to its BackpressurePolicy,
+// first applying its error-gating options (EmitErrors, MinErrorInterval,
+// SuppressUnchanged) if u is a Stale event. It only ever blocks (other than
+// on ctx) under BlockPublisher.
+func (s *notifySubscription) deliver(ctx context.Context, u UpdateEvent) {
+	u.CorrelationID = s.correlationID
+
+	if u.Stale && !s.shouldEmitError(u.Err) {
+		return
+	}
+
+	switch s.opts.Policy {
+	case DropOldest:
+		s.mu.Lock()
+		if len(s.queue) >= s.queueCap {
+			s.queue = s.queue[1:]
+		}
+		s.queue = append(s.queue, u)
+		s.mu.Unlock()
+		select {
+		case s.wake <- struct{}{}:
+		default:
+		}
+
+	case Coalesce:
+		s.mu.Lock()
+		s.pending = &u
+		s.mu.Unlock()
+		select {
+		case s.wake <- struct{}{}:
+		default:
+		}
+
+	default: // BlockPublisher
+		select {
+		case s.out <- u:
+		case <-ctx.Done():
+		}
+	}
+}
+
+// shouldEmitError reports whether a Stale event carrying err should be
+// delivered to the subscriber, applying EmitErrors, SuppressUnchanged and
+// MinErrorInterval. It records the decision so the next call can compare
+// against it.
+func (s *notifySubscription) shouldEmitError(err error) bool {
+	if !s.opts.EmitErrors {
+		return false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.opts.SuppressUnchanged && s.lastErr != nil && err != nil && err.Error() == s.lastErr.Error() {
+		return false
+	}
+	if s.opts.MinErrorInterval > 0 && !s.lastErrEmit.IsZero() && time.Since(s.lastErrEmit) < s.opts.MinErrorInterval {
+		return false
+	}
+
+	s.lastErr = err
+	s.lastErrEmit = time.Now()
+	return true
+}
+
+// dequeue pops the next event buffered for DropOldest/Coalesce, if any.
+func (s *notifySubscription) dequeue() (UpdateEvent, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch s.opts.Policy {
+	case DropOldest:
+		if len(s.queue) == 0 {
+			return UpdateEvent{}, false
+		}
+		u := s.queue[0]
+		s.queue = s.queue[1:]
+		return u, true
+	case Coalesce:
+		if s.pending == nil {
+			return UpdateEvent{}, false
+		}
+		u := *s.pending
+		s.pending = nil
+		return u, true
+	default:
+		return UpdateEvent{}, false
+	}
+}
+
+// run delivers buffered DropOldest/Coalesce updates to the subscriber until
+// ctx is cancelled. It is a no-op for BlockPublisher, which delivers
+// directly from deliver instead.
+func (s *notifySubscription) run(ctx context.Context) {
+	if s.opts.Policy != DropOldest && s.opts.Policy != Coalesce {
+		return
+	}
+	for {
+		select {
+		case <-s.wake:
+			for {
+				u, ok := s.dequeue()
+				if !ok {
+					break
+				}
+				select {
+				case s.out <- u:
+				case <-ctx.Done():
+					return
+				}
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// notifyPublisher multiplexes a single underlying blocking query across
+// every Notify subscriber watching the same (type, Request), so that N
+// subscribers drive one runNotifyPublisher loop instead of N. It is
+// reference-counted by subscriber and torn down once the last subscriber
+// unsubscribes.
+type notifyPublisher struct {
+	mu          sync.Mutex
+	subscribers map[uint64]*notifySubscription
+	nextID      uint64
+	cancel      context.CancelFunc
+
+	// closed is set, atomically with the subscriber count reaching zero,
+	// by the unsubscribe func that removes the last subscriber. Once set
+	// this publisher must never be handed out again: its query loop is
+	// guaranteed to observe the cancellation and exit without broadcasting
+	// further, however long that actually takes. See getOrCreatePublisher.
+	closed bool
+
+	// lastEvent is the most recent successful (non-stale) UpdateEvent
+	// broadcast by this publisher, if any. It's replayed to every newly
+	// added subscriber so a late joiner immediately gets the currently
+	// cached value instead of having to wait for the next change.
+	lastEvent *UpdateEvent
+}
+
+// subscribe adds sub to the publisher and returns a func that removes it
+// again, plus ok reporting whether the subscriber was actually added. ok is
+// false if this publisher already tore down (its last subscriber having
+// already left) by the time subscribe runs; the caller must discard it and
+// call getOrCreatePublisher again to obtain a fresh one. If the subscriber
+// is added, and the publisher already has a cached value, that value is
+// replayed to it immediately.
+func (p *notifyPublisher) subscribe(correlationID string, ch chan<- UpdateEvent, opts SubscribeOptions) (unsubscribe func(), ok bool) {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil, false
+	}
+
+	sub := newNotifySubscription(correlationID, ch, opts)
+	subCtx, subCancel := context.WithCancel(context.Background())
+
+	id := p.nextID
+	p.nextID++
+	p.subscribers[id] = sub
+	last := p.lastEvent
+	p.mu.Unlock()
+
+	go sub.run(subCtx)
+
+	if last != nil {
+		sub.deliver(subCtx, *last)
+	}
+
+	return func() {
+		subCancel()
+
+		p.mu.Lock()
+		delete(p.subscribers, id)
+		if len(p.subscribers) == 0 {
+			p.closed = true
+			p.cancel()
+		}
+		p.mu.Unlock()
+	}, true
+}
+
+// broadcast delivers u to every current subscriber. cacheAsLast should be
+// true for a successful fetch so u is replayed to subscribers that join
+// later, and false for an error-only event that shouldn't overwrite the
+// last known-good value.
+func (p *notifyPublisher) broadcast(ctx context.Context, u UpdateEvent, cacheAsLast bool) {
+	p.mu.Lock()
+	if cacheAsLast {
+		last := u
+		p.lastEvent = &last
+	}
+	subs := make([]*notifySubscription, 0, len(p.subscribers))
+	for _, sub := range p.subscribers {
+		subs = append(subs, sub)
+	}
+	p.mu.Unlock()
+
+	for _, sub := range subs {
+		sub.deliver(ctx, u)
+	}
+}
+
+// isClosed reports whether this publisher has already torn down.
+func (p *notifyPublisher) isClosed() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.closed
+}
+
+// getOrCreatePublisher returns the shared notifyPublisher for (t, r),
+// creating it and starting its query loop if there is no live publisher for
+// the key. A publisher found in c.notifyPublishers may have already decided
+// to tear down (p.isClosed()) even though its query loop, and therefore the
+// deferred removal in runNotifyPublisher, hasn't run yet; callers must not
+// treat a non-nil, non-closed-at-this-instant return as a guarantee, since
+// subscribe() itself can still observe the publisher transitioning to
+// closed between this check and the subscribe call. NotifySubscribe handles
+// that by retrying with a fresh getOrCreatePublisher call whenever subscribe
+// reports ok == false.
+func (c *Cache) getOrCreatePublisher(t string, r Request, backoff BackoffStrategy) *notifyPublisher {
+	key := t + ":" + r.CacheInfo().Key
+
+	c.notifyLock.Lock()
+	defer c.notifyLock.Unlock()
+
+	if c.notifyPublishers == nil {
+		c.notifyPublishers = make(map[string]*notifyPublisher)
+	}
+	if p, ok := c.notifyPublishers[key]; ok && !p.isClosed() {
+		return p
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &notifyPublisher{
+		subscribers: make(map[uint64]*notifySubscription),
+		cancel:      cancel,
+	}
+	c.notifyPublishers[key] = p
+	go c.runNotifyPublisher(ctx, t, r, key, p, c.scopedBackoffLocked(key, backoff))
+	return p
+}
+
+// scopedBackoffLocked returns the BackoffStrategy runNotifyPublisher should
+// use for the publisher keyed by key. backoff is shared as-is if it
+// doesn't carry per-call state (ExponentialBackoff, for example). If it
+// does (statefulBackoffStrategy, satisfied by DecorrelatedJitterBackoff), a
+// clone is made and cached per key instead, so one key's failure streak can
+// never reset or compound off of another key's even though both share the
+// same RegisterOptions.Backoff instance. Callers must hold notifyLock.
+func (c *Cache) scopedBackoffLocked(key string, backoff BackoffStrategy) BackoffStrategy {
+	stateful, ok := backoff.(statefulBackoffStrategy)
+	if !ok {
+		return backoff
+	}
+
+	if c.notifyBackoffs == nil {
+		c.notifyBackoffs = make(map[string]BackoffStrategy)
+	}
+	if cloned, ok := c.notifyBackoffs[key]; ok {
+		return cloned
+	}
+	cloned := stateful.clone()
+	c.notifyBackoffs[key] = cloned
+	return cloned
+}
+
+// runNotifyPublisher is the notify loop for blocking cache types: it
+// drives a single blocking query and broadcasts each UpdateEvent to every
+// subscriber currently registered on p.
+func (c *Cache) runNotifyPublisher(ctx context.Context, t string, r Request, key string, p *notifyPublisher, backoff BackoffStrategy) {
+	defer func() {
+		c.notifyLock.Lock()
+		// Only remove the entry if it's still this publisher: a
+		// getOrCreatePublisher call that observed p already closed may
+		// have replaced it with a fresh one under the same key, and that
+		// replacement must not be deleted here.
+		if c.notifyPublishers[key] == p {
+			delete(c.notifyPublishers, key)
+			delete(c.notifyBackoffs, key)
+		}
+		c.notifyLock.Unlock()
+	}()
+
+	if backoff == nil {
+		backoff = defaultBackoffStrategy
+	}
+
+	index := uint64(0)
+	failures := uint(0)
+	var lastGoodRes interface{}
+	var lastGoodMeta ResultMeta
+	var lastSuccess time.Time
+
+	for {
+		if ctx.Err() != nil {
+			return
+		}
+
+		res, meta, err := c.getWithIndex(t, r, index)
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		changed := index < meta.Index
+		if err == nil && meta.Index > 0 {
+			failures = 0
+			lastSuccess = time.Now()
+			lastGoodRes, lastGoodMeta = res, meta
+		} else {
+			failures++
+		}
+
+		switch {
+		case changed:
+			p.broadcast(ctx, UpdateEvent{
+				Result:              res,
+				Meta:                meta,
+				Err:                 err,
+				ConsecutiveFailures: int(failures),
+				LastSuccess:         lastSuccess,
+			}, true)
+			index = meta.Index
+		case err != nil:
+			p.broadcast(ctx, UpdateEvent{
+				Result:              lastGoodRes,
+				Meta:                lastGoodMeta,
+				Err:                 err,
+				Stale:               true,
+				ConsecutiveFailures: int(failures),
+				LastSuccess:         lastSuccess,
+			}, false)
+		}
+
+		if failures > 0 {
+			if wait := backoff.Wait(failures); wait > 0 {
+				select {
+				case <-time.After(wait):
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+		if index < 1 {
+			index = 1
+		}
+	}
 }
 
 // Notify registers a desire to be updated about changes to a cache result.
@@ -52,6 +675,23 @@ type UpdateEvent struct {
 // the notify loop will terminate.
 func (c *Cache) Notify(ctx context.Context, t string, r Request,
 	correlationID string, ch chan<- UpdateEvent) error {
+	return c.NotifySubscribe(ctx, t, r, correlationID, ch, SubscribeOptions{Policy: BlockPublisher})
+}
+
+// NotifySubscribe is like Notify but lets the caller pick a
+// BackpressurePolicy for its own channel, and opt in to receiving events for
+// failed fetches, via opts. Every subscriber watching the same (type,
+// Request) shares a single underlying blocking query instead of each driving
+// its own; the shared query is started on the first subscriber and torn
+// down once the last one's ctx is cancelled.
+//
+// Polling cache types (that don't support blocking) are not shared between
+// subscribers and always get their own notifyPollingQuery loop, but honor
+// the same opts.EmitErrors/MinErrorInterval/SuppressUnchanged semantics.
+// opts.Policy is ignored for them: every send blocks as under
+// BlockPublisher.
+func (c *Cache) NotifySubscribe(ctx context.Context, t string, r Request,
+	correlationID string, ch chan<- UpdateEvent, opts SubscribeOptions) error {
 
 	// Get the type that we're fetching
 	c.typesLock.RLock()
@@ -61,31 +701,64 @@ func (c *Cache) Notify(ctx context.Context, t string, r Request,
 		return fmt.Errorf("unknown type in cache: %s", t)
 	}
 	if tEntry.Type.SupportsBlocking() {
-		go c.notifyBlockingQuery(ctx, t, r, correlationID, ch)
+		// subscribe fails with ok == false if it lands on a publisher
+		// that already decided to tear down between getOrCreatePublisher
+		// returning it and this call (its last subscriber having left in
+		// between); retry against a freshly created one until it
+		// succeeds. See notifyPublisher.subscribe and getOrCreatePublisher.
+		var unsubscribe func()
+		for {
+			p := c.getOrCreatePublisher(t, r, tEntry.Opts.Backoff)
+			var ok bool
+			unsubscribe, ok = p.subscribe(correlationID, ch, opts)
+			if ok {
+				break
+			}
+		}
+		go func() {
+			<-ctx.Done()
+			unsubscribe()
+		}()
 	} else {
 		info := r.CacheInfo()
 		if info.MaxAge == 0 {
 			return fmt.Errorf("Cannot use Notify for polling cache types without specifying the MaxAge")
 		}
-		go c.notifyPollingQuery(ctx, t, r, correlationID, ch, info.MaxAge)
+		backoff := tEntry.Opts.Backoff
+		if stateful, ok := backoff.(statefulBackoffStrategy); ok {
+			backoff = stateful.clone()
+		}
+		go c.notifyPollingQuery(ctx, t, r, correlationID, ch, info.MaxAge, backoff, tEntry.Opts.Equal, opts)
 	}
 
 	return nil
 }
 
-func (c *Cache) notifyBlockingQuery(ctx context.Context, t string, r Request, correlationID string, ch chan<- UpdateEvent) {
-	// Always start at 0 index to deliver the initial (possibly currently cached
-	// value).
+func (c *Cache) notifyPollingQuery(ctx context.Context, t string, r Request, correlationID string, ch chan<- UpdateEvent, maxAge time.Duration, backoff BackoffStrategy, equal EqualFunc, opts SubscribeOptions) {
+	if backoff == nil {
+		backoff = defaultBackoffStrategy
+	}
+	if equal == nil {
+		equal = defaultEqual
+	}
+
 	index := uint64(0)
 	failures := uint(0)
 
+	var lastValue interface{} = nil
+	var lastGoodRes interface{}
+	var lastGoodMeta ResultMeta
+	var lastSuccess time.Time
+	var lastErrEmit time.Time
+	var lastErr error
+
 	for {
 		// Check context hasn't been cancelled
 		if ctx.Err() != nil {
 			return
 		}
 
-		// Blocking request
+		// Make the request
 		res, meta, err := c.getWithIndex(t, r, index)
 
 		// Check context hasn't been cancelled
@@ -93,89 +766,81 @@ func (c *Cache) notifyBlockingQuery(ctx context.Context, t string, r Request, co
 			return
 		}
 
-		// Check the index of the value returned in the cache entry to be sure it
-		// changed
-		if index < meta.Index {
-			u := UpdateEvent{correlationID, res, meta, err}
-			select {
-			case ch <- u:
-			case <-ctx.Done():
-				return
-			}
-
-			// Update index for next request
-			index = meta.Index
-		}
-
-		// Handle errors with backoff. Badly behaved blocking calls that returned
-		// a zero index are considered as failures since we need to not get stuck
-		// in a busy loop.
-		if err == nil && meta.Index > 0 {
+		// Reset or increment failure counter
+		if err == nil {
 			failures = 0
+			lastSuccess = time.Now()
+			lastGoodRes, lastGoodMeta = res, meta
 		} else {
 			failures++
 		}
-		if wait := backOffWait(failures); wait > 0 {
-			select {
-			case <-time.After(wait):
-			case <-ctx.Done():
-				return
-			}
-		}
-		// Sanity check we always request blocking on second pass
-		if index < 1 {
-			index = 1
-		}
-	}
-}
-
-func (c *Cache) notifyPollingQuery(ctx context.Context, t string, r Request, correlationID string, ch chan<- UpdateEvent, maxAge time.Duration) {
-	index := uint64(0)
-	failures := uint(0)
 
-	var lastValue interface{} = nil
+		// Check for a change in the value or an index change
+		changed := index < meta.Index || !equal(lastValue, res)
 
-	for {
-		// Check context hasn't been cancelled
-		if ctx.Err() != nil {
-			return
-		}
+		// A persistent error leaves res/meta identical to the last fetch,
+		// so gating error emission on changed (as the value-changed path
+		// below does) would mean MinErrorInterval's periodic
+		// re-notification never fires past the first failure. Error
+		// emission is therefore considered on every iteration where
+		// err != nil, independent of changed, matching the shared
+		// blocking-query publisher in runNotifyPublisher which broadcasts
+		// on every err != nil iteration regardless of "changed".
+		if err != nil {
+			emit := opts.EmitErrors
+			if emit && opts.SuppressUnchanged && lastErr != nil && err.Error() == lastErr.Error() {
+				emit = false
+			}
+			if emit && opts.MinErrorInterval > 0 && !lastErrEmit.IsZero() && time.Since(lastErrEmit) < opts.MinErrorInterval {
+				emit = false
+			}
 
-		// Make the request
-		res, meta, err := c.getWithIndex(t, r, index)
+			if emit {
+				lastErr = err
+				lastErrEmit = time.Now()
 
-		// Check context hasn't been cancelled
-		if ctx.Err() != nil {
-			return
-		}
-
-		// Check for a change in the value or an index change
-		if index < meta.Index || !reflect.DeepEqual(lastValue, res) {
-			u := UpdateEvent{correlationID, res, meta, err}
+				u := UpdateEvent{
+					CorrelationID:       correlationID,
+					Result:              lastGoodRes,
+					Meta:                lastGoodMeta,
+					Err:                 err,
+					Stale:               true,
+					ConsecutiveFailures: int(failures),
+					LastSuccess:         lastSuccess,
+				}
+				select {
+				case ch <- u:
+				case <-ctx.Done():
+					return
+				}
+			}
+		} else if changed {
+			u := UpdateEvent{
+				CorrelationID:       correlationID,
+				Result:              res,
+				Meta:                meta,
+				ConsecutiveFailures: 0,
+				LastSuccess:         lastSuccess,
+			}
 			select {
 			case ch <- u:
 			case <-ctx.Done():
 				return
 			}
+		}
 
+		if changed {
 			// Update index and lastValue
 			lastValue = res
 			index = meta.Index
 		}
 
-		// Reset or increment failure counter
-		if err == nil {
-			failures = 0
-		} else {
-			failures++
-		}
-
 		// Determining how long to wait before the next poll is complicated.
 		// First off the happy path and the error path waits are handled distinctly
 		//
 		// Once fetching the data through the cache returns an error (and until a
 		// non-error value is returned) the wait time between each round of the loop
-		// gets controlled by the backOffWait function. Because we would have waited
+		// gets controlled by the registered BackoffStrategy. Because we would have waited
 		// at least until the age of the cached data was too old the error path should
 		// immediately retry the fetch and backoff on the time as needed for persistent
 		// failures which potentially will wait much longer than the MaxAge of the request
@@ -192,7 +857,7 @@ func (c *Cache) notifyPollingQuery(ctx context.Context, t string, r Request, cor
 		// the efficiencies gained by it.
 		if failures > 0 {
 
-			errWait := backOffWait(failures)
+			errWait := backoff.Wait(failures)
 			select {
 			case <-time.After(errWait):
 			case <-ctx.Done():
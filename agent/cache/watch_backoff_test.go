@@ -0,0 +1,184 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// fakePollingType is a minimal non-blocking Type whose Fetch is supplied by
+// the test, used to exercise notifyPollingQuery's backoff handling.
+type fakePollingType struct {
+	fetch func(opts FetchOptions, r Request) (FetchResult, error)
+}
+
+func (f fakePollingType) SupportsBlocking() bool { return false }
+
+func (f fakePollingType) Fetch(opts FetchOptions, r Request) (FetchResult, error) {
+	return f.fetch(opts, r)
+}
+
+func TestExponentialBackoff_Wait(t *testing.T) {
+	b := ExponentialBackoff{Base: time.Second, Max: time.Minute}
+
+	for _, failures := range []uint{1, 2, 3, 10, 100} {
+		wait := b.Wait(failures)
+		if wait < 0 {
+			t.Fatalf("failures=%d: got negative wait %v", failures, wait)
+		}
+		if wait > b.Max {
+			t.Fatalf("failures=%d: got %v, want capped at Max %v", failures, wait, b.Max)
+		}
+	}
+}
+
+func TestExponentialBackoff_WaitDefaults(t *testing.T) {
+	var b ExponentialBackoff // zero value: Base/Max unset.
+
+	wait := b.Wait(1)
+	if wait < 0 || wait > time.Minute {
+		t.Fatalf("got %v, want within the documented default Base=1s/Max=1m range", wait)
+	}
+}
+
+func TestDecorrelatedJitterBackoff_Wait(t *testing.T) {
+	b := &DecorrelatedJitterBackoff{Base: time.Second, Max: time.Minute}
+
+	first := b.Wait(1)
+	if first < b.Base || first > 3*b.Base {
+		t.Fatalf("first wait %v, want within [Base, 3*Base] = [%v, %v]", first, b.Base, 3*b.Base)
+	}
+
+	// Subsequent calls must stay bounded by Base and Max regardless of how
+	// the previous wait escalated.
+	for i := uint(2); i <= 20; i++ {
+		wait := b.Wait(i)
+		if wait < b.Base || wait > b.Max {
+			t.Fatalf("failures=%d: got %v, want within [Base, Max] = [%v, %v]", i, wait, b.Base, b.Max)
+		}
+	}
+}
+
+// TestDecorrelatedJitterBackoff_ResetOnSuccess verifies that calling Wait
+// with failures <= 1 (a caller whose failure streak just reset after a
+// success) discards any escalated prev state rather than compounding off
+// of it, per the type's documented reset-on-success behavior.
+func TestDecorrelatedJitterBackoff_ResetOnSuccess(t *testing.T) {
+	b := &DecorrelatedJitterBackoff{Base: time.Millisecond, Max: time.Hour}
+
+	// Escalate prev as high as it can go by simulating a long failure
+	// streak.
+	for i := uint(1); i <= 50; i++ {
+		b.Wait(i)
+	}
+	escalated := b.prev
+	if escalated <= b.Base {
+		t.Fatalf("test setup didn't escalate prev: got %v", escalated)
+	}
+
+	reset := b.Wait(1)
+	if reset > 3*b.Base {
+		t.Fatalf("Wait(1) returned %v derived from the escalated prev %v, want it to have reset to around Base %v", reset, escalated, b.Base)
+	}
+}
+
+// TestScopedBackoff_PerPublisherIsolation verifies that scopedBackoffLocked
+// clones a stateful BackoffStrategy (DecorrelatedJitterBackoff) separately
+// per key, so one key's failure streak can't reset or compound off of
+// another key's even though both are registered with the same
+// RegisterOptions.Backoff instance.
+func TestScopedBackoff_PerPublisherIsolation(t *testing.T) {
+	c := &Cache{}
+	shared := &DecorrelatedJitterBackoff{Base: time.Millisecond, Max: time.Hour}
+
+	c.notifyLock.Lock()
+	a := c.scopedBackoffLocked("t:a", shared)
+	b := c.scopedBackoffLocked("t:b", shared)
+	c.notifyLock.Unlock()
+
+	if a == BackoffStrategy(shared) || b == BackoffStrategy(shared) {
+		t.Fatal("scopedBackoffLocked returned the shared registered instance instead of a clone")
+	}
+	if a == b {
+		t.Fatal("scopedBackoffLocked returned the same clone for two different keys")
+	}
+
+	// Escalate a's prev via a long failure streak.
+	ad := a.(*DecorrelatedJitterBackoff)
+	for i := uint(1); i <= 50; i++ {
+		ad.Wait(i)
+	}
+	if ad.prev <= ad.Base {
+		t.Fatalf("test setup didn't escalate a's prev: got %v", ad.prev)
+	}
+
+	// b's first failure must not be affected by a's escalated state.
+	bd := b.(*DecorrelatedJitterBackoff)
+	first := bd.Wait(1)
+	if first > 3*bd.Base {
+		t.Fatalf("b.Wait(1) returned %v, want bounded by its own Base %v; got a's escalated state instead", first, bd.Base)
+	}
+
+	// A repeated lookup for the same key returns the same clone, so a
+	// publisher's failure-streak state persists across calls within its
+	// lifetime.
+	c.notifyLock.Lock()
+	again := c.scopedBackoffLocked("t:a", shared)
+	c.notifyLock.Unlock()
+	if again != BackoffStrategy(ad) {
+		t.Fatal("scopedBackoffLocked returned a new clone for an already-scoped key")
+	}
+}
+
+// TestScopedBackoff_StatelessIsShared verifies that a stateless
+// BackoffStrategy (ExponentialBackoff, which doesn't implement
+// statefulBackoffStrategy) is returned as-is rather than cloned, since it
+// has no state to isolate.
+func TestScopedBackoff_StatelessIsShared(t *testing.T) {
+	c := &Cache{}
+	shared := ExponentialBackoff{Base: time.Second, Max: time.Minute}
+
+	c.notifyLock.Lock()
+	a := c.scopedBackoffLocked("t:a", shared)
+	b := c.scopedBackoffLocked("t:b", shared)
+	c.notifyLock.Unlock()
+
+	if a != BackoffStrategy(shared) || b != BackoffStrategy(shared) {
+		t.Fatal("scopedBackoffLocked cloned a stateless BackoffStrategy instead of sharing it")
+	}
+}
+
+// TestNotifyPollingQuery_UsesRegisteredBackoff confirms notifyPollingQuery
+// consults the BackoffStrategy passed in (ultimately RegisterOptions.Backoff)
+// instead of the hard-coded backOffWait. A fake BackoffStrategy stands in
+// for an injected clock: since Wait is a pure function of the failure
+// count, recording what it's called with lets the test observe the notify
+// loop's backoff behavior without depending on wall-clock timing.
+func TestNotifyPollingQuery_UsesRegisteredBackoff(t *testing.T) {
+	ch := make(chan UpdateEvent, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	typ := fakePollingType{fetch: func(opts FetchOptions, r Request) (FetchResult, error) {
+		return FetchResult{}, errors.New("boom")
+	}}
+	c := &Cache{types: map[string]typeEntry{"t": {Type: typ}}}
+
+	waited := make(chan uint, 10)
+	fake := BackoffStrategyFunc(func(failures uint) time.Duration {
+		waited <- failures
+		return time.Millisecond
+	})
+
+	go c.notifyPollingQuery(ctx, "t", fakeRequest{key: "k"}, "cid", ch, time.Hour, fake, nil, SubscribeOptions{})
+
+	select {
+	case failures := <-waited:
+		if failures != 1 {
+			t.Fatalf("got failures=%d, want 1", failures)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("registered BackoffStrategy was never consulted")
+	}
+}
@@ -0,0 +1,44 @@
+package cache
+
+import "testing"
+
+func TestHashEqual(t *testing.T) {
+	equal := HashEqual()
+
+	type thing struct {
+		A string
+		B int
+	}
+
+	same1 := thing{A: "x", B: 1}
+	same2 := thing{A: "x", B: 1}
+	if !equal(same1, same2) {
+		t.Fatalf("equal values reported as unequal")
+	}
+
+	different := thing{A: "x", B: 2}
+	if equal(same1, different) {
+		t.Fatalf("different values reported as equal")
+	}
+}
+
+func TestHashEqual_UnhashableIsNeverEqual(t *testing.T) {
+	equal := HashEqual()
+
+	// Values hashstructure can't hash (here, a func) must conservatively
+	// report as unequal rather than silently swallow a real change.
+	a := func() {}
+	b := func() {}
+	if equal(a, b) {
+		t.Fatal("unhashable values reported as equal, want conservative false")
+	}
+}
+
+func TestDefaultEqual(t *testing.T) {
+	if !defaultEqual(1, 1) {
+		t.Fatal("defaultEqual(1, 1) = false, want true")
+	}
+	if defaultEqual(1, 2) {
+		t.Fatal("defaultEqual(1, 2) = true, want false")
+	}
+}
@@ -0,0 +1,300 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeBlockingType is a minimal Type whose Fetch blocks until a newer value
+// than opts.MinIndex is produced by update, the way a real blocking query
+// behaves.
+type fakeBlockingType struct {
+	mu      sync.Mutex
+	value   int
+	index   uint64
+	fetches int32
+	waiters map[chan struct{}]struct{}
+}
+
+func newFakeBlockingType() *fakeBlockingType {
+	return &fakeBlockingType{index: 1, waiters: make(map[chan struct{}]struct{})}
+}
+
+func (f *fakeBlockingType) SupportsBlocking() bool { return true }
+
+func (f *fakeBlockingType) Fetch(opts FetchOptions, r Request) (FetchResult, error) {
+	atomic.AddInt32(&f.fetches, 1)
+
+	f.mu.Lock()
+	if opts.MinIndex == 0 || opts.MinIndex < f.index {
+		value, index := f.value, f.index
+		f.mu.Unlock()
+		return FetchResult{Value: value, Index: index}, nil
+	}
+	wake := make(chan struct{})
+	f.waiters[wake] = struct{}{}
+	f.mu.Unlock()
+
+	<-wake
+
+	f.mu.Lock()
+	value, index := f.value, f.index
+	f.mu.Unlock()
+	return FetchResult{Value: value, Index: index}, nil
+}
+
+// update publishes a new value, waking any Fetch currently blocked on it.
+func (f *fakeBlockingType) update(value int) {
+	f.mu.Lock()
+	f.value = value
+	f.index++
+	woken := f.waiters
+	f.waiters = make(map[chan struct{}]struct{})
+	f.mu.Unlock()
+	for ch := range woken {
+		close(ch)
+	}
+}
+
+func (f *fakeBlockingType) numFetches() int32 { return atomic.LoadInt32(&f.fetches) }
+
+type fakeRequest struct{ key string }
+
+func (r fakeRequest) CacheInfo() RequestInfo { return RequestInfo{Key: r.key} }
+
+func newTestCache(t string, typ Type) *Cache {
+	return &Cache{types: map[string]typeEntry{t: {Type: typ}}}
+}
+
+func TestNotifySubscribe_SharedPublisherTornDownAfterLastUnsubscribe(t *testing.T) {
+	typ := newFakeBlockingType()
+	c := newTestCache("t", typ)
+	req := fakeRequest{key: "k"}
+
+	const n = 5
+	ctxs := make([]context.Context, n)
+	cancels := make([]context.CancelFunc, n)
+	chans := make([]chan UpdateEvent, n)
+	for i := 0; i < n; i++ {
+		ctxs[i], cancels[i] = context.WithCancel(context.Background())
+		chans[i] = make(chan UpdateEvent, 1)
+		if err := c.NotifySubscribe(ctxs[i], "t", req, "cid", chans[i], SubscribeOptions{}); err != nil {
+			t.Fatalf("NotifySubscribe: %v", err)
+		}
+	}
+
+	// Every subscriber first gets the initial value 0, either broadcast by
+	// the publisher's first fetch or replayed to it on joining.
+	for i := 0; i < n; i++ {
+		select {
+		case u := <-chans[i]:
+			if u.Result != 0 {
+				t.Fatalf("subscriber %d got initial %v, want 0", i, u.Result)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("subscriber %d never got the initial value", i)
+		}
+	}
+
+	typ.update(1)
+	for i := 0; i < n; i++ {
+		select {
+		case u := <-chans[i]:
+			if u.Result != 1 {
+				t.Fatalf("subscriber %d got %v, want 1", i, u.Result)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("subscriber %d never got an event", i)
+		}
+	}
+
+	if got := typ.numFetches(); got > int32(n) {
+		t.Fatalf("expected subscribers to share a single query, got %d fetches for %d subscribers", got, n)
+	}
+
+	for i := 0; i < n; i++ {
+		cancels[i]()
+	}
+
+	// getWithIndex isn't itself ctx-aware, so runNotifyPublisher's loop
+	// only notices the cancellation between fetches; keep nudging the
+	// fake server forward so an in-flight blocking fetch keeps returning
+	// until that check finally runs.
+	deadline := time.Now().Add(2 * time.Second)
+	value := 1
+	for {
+		c.notifyLock.Lock()
+		remaining := len(c.notifyPublishers)
+		c.notifyLock.Unlock()
+		if remaining == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("publisher was not torn down after all subscribers unsubscribed")
+		}
+		value++
+		typ.update(value)
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestNotifySubscribe_LateJoinerReplaysLastValue(t *testing.T) {
+	typ := newFakeBlockingType()
+	c := newTestCache("t", typ)
+	req := fakeRequest{key: "k"}
+
+	ctx1, cancel1 := context.WithCancel(context.Background())
+	defer cancel1()
+	ch1 := make(chan UpdateEvent, 1)
+	if err := c.NotifySubscribe(ctx1, "t", req, "cid", ch1, SubscribeOptions{}); err != nil {
+		t.Fatalf("NotifySubscribe: %v", err)
+	}
+
+	select {
+	case u := <-ch1:
+		if u.Result != 0 {
+			t.Fatalf("got initial %v, want 0", u.Result)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("first subscriber never got the initial value")
+	}
+
+	typ.update(42)
+	select {
+	case u := <-ch1:
+		if u.Result != 42 {
+			t.Fatalf("got %v, want 42", u.Result)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("first subscriber never got an event")
+	}
+
+	// A second subscriber joining after the publisher already has a
+	// cached value must be replayed that value immediately, rather than
+	// waiting for the next change.
+	ctx2, cancel2 := context.WithCancel(context.Background())
+	defer cancel2()
+	ch2 := make(chan UpdateEvent, 1)
+	if err := c.NotifySubscribe(ctx2, "t", req, "cid2", ch2, SubscribeOptions{}); err != nil {
+		t.Fatalf("NotifySubscribe: %v", err)
+	}
+
+	select {
+	case u := <-ch2:
+		if u.Result != 42 {
+			t.Fatalf("late joiner got %v, want replayed value 42", u.Result)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("late joiner never got the replayed current value")
+	}
+}
+
+func TestBackpressurePolicy_BlockPublisher(t *testing.T) {
+	p := newTestPublisher()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch := make(chan UpdateEvent) // unbuffered: a blocked send proves BlockPublisher blocks.
+
+	unsubscribe, ok := p.subscribe("cid", ch, SubscribeOptions{Policy: BlockPublisher})
+	if !ok {
+		t.Fatal("subscribe failed")
+	}
+	defer unsubscribe()
+
+	done := make(chan struct{})
+	go func() {
+		p.broadcast(ctx, UpdateEvent{Result: 1}, true)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("broadcast returned without the subscriber reading, want it to block")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	select {
+	case u := <-ch:
+		if u.Result != 1 {
+			t.Fatalf("got %v, want 1", u.Result)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("never received the blocked event")
+	}
+	<-done
+}
+
+func TestBackpressurePolicy_DropOldest(t *testing.T) {
+	p := newTestPublisher()
+	ctx := context.Background()
+	ch := make(chan UpdateEvent, 1)
+
+	unsubscribe, ok := p.subscribe("cid", ch, SubscribeOptions{Policy: DropOldest})
+	if !ok {
+		t.Fatal("subscribe failed")
+	}
+	defer unsubscribe()
+
+	for i := 1; i <= 5; i++ {
+		p.broadcast(ctx, UpdateEvent{Result: i}, true)
+	}
+
+	// Nothing reads ch until every broadcast is done, so at most three
+	// updates survive: one in ch, one held by the subscription's run loop
+	// waiting to send, and one in its internal buffer (sized to cap(ch)).
+	// The newest is always kept and arrives last.
+	deadline := time.After(time.Second)
+	var got []interface{}
+	for {
+		select {
+		case u := <-ch:
+			got = append(got, u.Result)
+		case <-deadline:
+			if len(got) == 0 || got[len(got)-1] != 5 {
+				t.Fatalf("got %v, want the newest value 5 delivered last", got)
+			}
+			if len(got) > 3 {
+				t.Fatalf("got %v, want the oldest buffered values dropped", got)
+			}
+			return
+		}
+	}
+}
+
+func TestBackpressurePolicy_Coalesce(t *testing.T) {
+	p := newTestPublisher()
+	ctx := context.Background()
+	ch := make(chan UpdateEvent, 1)
+
+	unsubscribe, ok := p.subscribe("cid", ch, SubscribeOptions{Policy: Coalesce})
+	if !ok {
+		t.Fatal("subscribe failed")
+	}
+	defer unsubscribe()
+
+	for i := 1; i <= 5; i++ {
+		p.broadcast(ctx, UpdateEvent{Result: i}, true)
+	}
+
+	deadline := time.After(time.Second)
+	var last UpdateEvent
+	for {
+		select {
+		case u := <-ch:
+			last = u
+		case <-deadline:
+			if last.Result != 5 {
+				t.Fatalf("got %v, want to eventually coalesce down to the latest value 5", last.Result)
+			}
+			return
+		}
+	}
+}
+
+func newTestPublisher() *notifyPublisher {
+	_, cancel := context.WithCancel(context.Background())
+	return &notifyPublisher{subscribers: make(map[uint64]*notifySubscription), cancel: cancel}
+}
@@ -0,0 +1,66 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// pollingTypeFunc adapts a plain func to a polling (non-blocking) Type.
+type pollingTypeFunc func(opts FetchOptions, r Request) (FetchResult, error)
+
+func (f pollingTypeFunc) Fetch(opts FetchOptions, r Request) (FetchResult, error) { return f(opts, r) }
+func (f pollingTypeFunc) SupportsBlocking() bool                                  { return false }
+
+// TestNotifyPollingQuery_PersistentErrorIsReNotified ensures EmitErrors with
+// MinErrorInterval keeps re-notifying a persistent, unchanging error rather
+// than only emitting once, matching the shared blocking-query publisher's
+// behavior of broadcasting on every failed fetch regardless of "changed".
+func TestNotifyPollingQuery_PersistentErrorIsReNotified(t *testing.T) {
+	ch := make(chan UpdateEvent, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var mu sync.Mutex
+	calls := 0
+	typ := pollingTypeFunc(func(opts FetchOptions, r Request) (FetchResult, error) {
+		mu.Lock()
+		calls++
+		n := calls
+		mu.Unlock()
+		if n == 1 {
+			return FetchResult{Value: 1, Index: 1}, nil
+		}
+		return FetchResult{}, fmt.Errorf("boom")
+	})
+	c := &Cache{types: map[string]typeEntry{"t": {Type: typ}}}
+
+	opts := SubscribeOptions{EmitErrors: true, MinErrorInterval: time.Millisecond}
+	fastBackoff := BackoffStrategyFunc(func(uint) time.Duration { return 10 * time.Millisecond })
+	go c.notifyPollingQuery(ctx, "t", fakeRequest{key: "k"}, "cid", ch, 10*time.Millisecond, fastBackoff, nil, opts)
+
+	select {
+	case u := <-ch:
+		if u.Err != nil || u.Result != 1 {
+			t.Fatalf("unexpected first event: %+v", u)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("never got the initial successful event")
+	}
+
+	seen := 0
+	deadline := time.After(time.Second)
+	for seen < 2 {
+		select {
+		case u := <-ch:
+			if u.Err == nil || !u.Stale {
+				t.Fatalf("expected a stale error event, got %+v", u)
+			}
+			seen++
+		case <-deadline:
+			t.Fatalf("only saw %d error events for a persistent, unchanging error; want at least 2", seen)
+		}
+	}
+}